@@ -0,0 +1,112 @@
+package dmr
+
+import "testing"
+
+func TestFragmentCodecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec FragmentCodec
+	}{
+		{"identity", identityCodec{}},
+		{"deflate", deflateCodec{}},
+		{"snappy", snappyCodec{}},
+	}
+	payload := []byte("the quick brown fox jumps over the lazy dog")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.codec.Encode(payload)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			decoded, err := tt.codec.Decode(encoded)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if string(decoded) != string(payload) {
+				t.Fatalf("round trip mismatch:\n got  %q\n want %q", decoded, payload)
+			}
+		})
+	}
+}
+
+// TestFragmentCodecCombineWithTrailingPadding exercises the case that broke
+// Snappy before it was framed with a length prefix: CombineDataBlocks always
+// includes the last block in full, so the decompressed input carries the
+// CRC-32 trailer and any zero padding ahead of it. A codec must ignore that
+// trailer rather than treat it as more compressed data.
+func TestFragmentCodecCombineWithTrailingPadding(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec FragmentCodec
+	}{
+		{"deflate", deflateCodec{}},
+		{"snappy", snappyCodec{}},
+	}
+	payload := []byte("short payload that compresses smaller than one Rate-1 block")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df := &DataFragment{Data: payload, Codec: tt.codec}
+			blocks, err := df.DataBlocks(Rate1Data, true)
+			if err != nil {
+				t.Fatalf("DataBlocks: %v", err)
+			}
+
+			got, err := CombineCompressedDataBlocks(blocks, compressionIDFor(t, tt.codec))
+			if err != nil {
+				t.Fatalf("CombineCompressedDataBlocks: %v", err)
+			}
+			if string(got.Data[:got.Stored]) != string(payload) {
+				t.Fatalf("round trip mismatch:\n got  %q\n want %q", got.Data[:got.Stored], payload)
+			}
+		})
+	}
+}
+
+func compressionIDFor(t *testing.T, codec FragmentCodec) uint8 {
+	t.Helper()
+	switch codec.(type) {
+	case deflateCodec:
+		return CompressionDeflate
+	case snappyCodec:
+		return CompressionSnappy
+	default:
+		t.Fatalf("no compression identifier registered for %T", codec)
+		return CompressionNone
+	}
+}
+
+func TestSnappyCodecDecodeTruncated(t *testing.T) {
+	codec := snappyCodec{}
+
+	if _, err := codec.Decode([]byte{0x01, 0x02}); err == nil {
+		t.Fatalf("expected an error decoding a payload shorter than the length prefix")
+	}
+
+	encoded, err := codec.Encode([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if _, err := codec.Decode(encoded[:len(encoded)-1]); err == nil {
+		t.Fatalf("expected an error decoding a payload truncated before the end of the compressed data")
+	}
+}
+
+func TestFragmentCodecForUnknownIdentifier(t *testing.T) {
+	if _, ok := FragmentCodecFor(0xfe); ok {
+		t.Fatalf("expected no codec registered for identifier 0xfe")
+	}
+}
+
+func TestRegisterFragmentCodec(t *testing.T) {
+	const id = uint8(0xfd)
+	RegisterFragmentCodec(id, deflateCodec{})
+	codec, ok := FragmentCodecFor(id)
+	if !ok {
+		t.Fatalf("expected a codec to be registered for %#02x", id)
+	}
+	if _, ok := codec.(deflateCodec); !ok {
+		t.Fatalf("expected the registered codec to be deflateCodec, got %T", codec)
+	}
+}
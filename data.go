@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sync"
 
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/encoding/unicode"
 )
 
@@ -19,7 +23,7 @@ const (
 var crc9Masks = map[uint8]uint16{
 	Rate12Data: 0x00f0,
 	Rate34Data: 0x01ff,
-	//Rate1Data: 0x010f,
+	Rate1Data:  0x010f,
 }
 
 func calculateCRC9(serial uint8, data []byte, dataType uint8) (crc uint16) {
@@ -42,6 +46,7 @@ func calculateCRC9(serial uint8, data []byte, dataType uint8) (crc uint16) {
 var dataBlockLengths = map[uint8]int{
 	Rate12Data: 12,
 	Rate34Data: 18,
+	Rate1Data:  24,
 	Data:       22,
 }
 
@@ -111,10 +116,33 @@ type DataFragment struct {
 	Stored int
 	Needed int
 	CRC    uint32
+
+	// Codec compresses Data before DataBlocks splits it into blocks. A
+	// nil Codec means the payload is carried as-is, same as before Codec
+	// was introduced. CombineDataBlocks does not consult Codec and never
+	// decompresses; call CombineCompressedDataBlocks with the negotiated
+	// compression identifier to reverse Encode. On a fragment returned by
+	// CombineCompressedDataBlocks, Codec records which codec was used,
+	// it does not trigger decoding on its own.
+	Codec FragmentCodec
 }
 
 func (df *DataFragment) DataBlocks(dataType uint8, confirm bool) ([]*DataBlock, error) {
-	df.Stored = len(df.Data)
+	data := df.Data
+	if df.Codec != nil {
+		encoded, err := df.Codec.Encode(data)
+		if err != nil {
+			return nil, err
+		}
+		data = encoded
+		// Keep Data/Stored a matching pair: every other path in this
+		// package treats Data[:Stored] as the bytes actually carried
+		// on air, and a codec's encoded form can be longer than the
+		// original payload.
+		df.Data = data
+	}
+
+	df.Stored = len(data)
 	if df.Stored > MaxPacketFragmentSize {
 		df.Stored = MaxPacketFragmentSize
 	}
@@ -131,12 +159,12 @@ func (df *DataFragment) DataBlocks(dataType uint8, confirm bool) ([]*DataBlock,
 	// Calculate fragment CRC32
 	for i := 0; i < (df.Needed*blockCap)-4; i += 2 {
 		if i+1 < df.Stored {
-			crc32(&df.CRC, df.Data[i+1])
+			crc32(&df.CRC, data[i+1])
 		} else {
 			crc32(&df.CRC, 0)
 		}
 		if i < df.Stored {
-			crc32(&df.CRC, df.Data[i])
+			crc32(&df.CRC, data[i])
 		} else {
 			crc32(&df.CRC, 0)
 		}
@@ -158,7 +186,7 @@ func (df *DataFragment) DataBlocks(dataType uint8, confirm bool) ([]*DataBlock,
 		if df.Stored-stored < store {
 			store = df.Stored - stored
 		}
-		copy(block.Data, df.Data[stored:stored+store])
+		copy(block.Data, data[stored:stored+store])
 		stored += store
 
 		if i == (df.Needed - 1) {
@@ -220,10 +248,45 @@ func CombineDataBlocks(blocks []*DataBlock) (*DataFragment, error) {
 	return f, nil
 }
 
-var encodingMap map[uint8]encoding.Encoding
+// DD Format identifiers outside the DMR AI spec's own table (page 175,
+// Table E.2), added for deployments that negotiate them out-of-band: the DD
+// formats commonly used for Asian-market radios and the legacy code page
+// used by older Motorola subscriber units.
+const (
+	DDFormatGB2312      uint8 = 0x80 // Simplified Chinese (GB2312).
+	DDFormatGBK         uint8 = 0x81 // Simplified Chinese (GBK, GB2312 superset).
+	DDFormatShiftJIS    uint8 = 0x82 // Japanese (Shift_JIS).
+	DDFormatEUCKR       uint8 = 0x83 // Korean (EUC-KR).
+	DDFormatWindows1252 uint8 = 0x84 // Western European (Windows-1252), used by legacy Motorola radios.
+)
+
+// ErrEncodingUnsupported is returned by BuildMessageData and ParseMessageData
+// when ddFormat has no registered encoding, so callers can branch on it with
+// errors.Is instead of matching an error string.
+var ErrEncodingUnsupported = errors.New("dmr: encoding not supported")
+
+var (
+	encodingMap   map[uint8]encoding.Encoding
+	encodingMapMu sync.RWMutex
+)
+
+// RegisterDDFormat adds or replaces the encoding used for a DD Format
+// identifier. It lets downstream applications plug in encodings that are
+// not built into this package, such as vendor-specific code pages, without
+// forking it. Registering over an id that already has an encoding replaces
+// it. It is safe to call concurrently with BuildMessageData/ParseMessageData
+// and with other calls to RegisterDDFormat.
+func RegisterDDFormat(id uint8, enc encoding.Encoding) {
+	encodingMapMu.Lock()
+	defer encodingMapMu.Unlock()
+	encodingMap[id] = enc
+}
 
 func BuildMessageData(msg string, ddFormat uint8, nullTerminated bool) ([]byte, error) {
-	if e, ok := encodingMap[ddFormat]; ok {
+	encodingMapMu.RLock()
+	e, ok := encodingMap[ddFormat]
+	encodingMapMu.RUnlock()
+	if ok {
 		enc := e.NewEncoder()
 		data, err := enc.Bytes([]byte(msg))
 		if err != nil {
@@ -234,11 +297,14 @@ func BuildMessageData(msg string, ddFormat uint8, nullTerminated bool) ([]byte,
 		}
 		return data, nil
 	}
-	return nil, fmt.Errorf("dmr: encoding %s text is not supported", DDFormatName[ddFormat])
+	return nil, fmt.Errorf("dmr: encoding %s text: %w", DDFormatName[ddFormat], ErrEncodingUnsupported)
 }
 
 func ParseMessageData(data []byte, ddFormat uint8, nullTerminated bool) (string, error) {
-	if e, ok := encodingMap[ddFormat]; ok {
+	encodingMapMu.RLock()
+	e, ok := encodingMap[ddFormat]
+	encodingMapMu.RUnlock()
+	if ok {
 		dec := e.NewDecoder()
 		str, err := dec.Bytes(data)
 		if err != nil {
@@ -251,7 +317,7 @@ func ParseMessageData(data []byte, ddFormat uint8, nullTerminated bool) (string,
 		}
 		return string(str), nil
 	}
-	return "", fmt.Errorf("dmr: decoding %s text is not supported", DDFormatName[ddFormat])
+	return "", fmt.Errorf("dmr: decoding %s text: %w", DDFormatName[ddFormat], ErrEncodingUnsupported)
 }
 
 func init() {
@@ -273,5 +339,10 @@ func init() {
 		DDFormatUTF16:          unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
 		DDFormatUTF16BE:        unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
 		DDFormatUTF16LE:        unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+		DDFormatGB2312:         simplifiedchinese.GBK, // GBK is a strict superset of GB2312; x/text has no standalone GB2312 codec.
+		DDFormatGBK:            simplifiedchinese.GBK,
+		DDFormatShiftJIS:       japanese.ShiftJIS,
+		DDFormatEUCKR:          korean.EUCKR,
+		DDFormatWindows1252:    charmap.Windows1252,
 	}
 }
@@ -0,0 +1,74 @@
+package dmr
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestRate1DataFragmentRoundTrip exercises DataBlocks/CombineDataBlocks for a
+// Rate-1 (full rate) fragment large enough to span several blocks, for both
+// confirmed and unconfirmed delivery.
+func TestRate1DataFragmentRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		confirmed bool
+		size      int
+	}{
+		{"confirmed", true, 50},
+		{"unconfirmed", false, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := make([]byte, tt.size)
+			for i := range payload {
+				payload[i] = byte(i)
+			}
+
+			df := &DataFragment{Data: payload}
+			blocks, err := df.DataBlocks(Rate1Data, tt.confirmed)
+			if err != nil {
+				t.Fatalf("DataBlocks: %v", err)
+			}
+			if len(blocks) < 2 {
+				t.Fatalf("expected a multi-block fragment, got %d block(s)", len(blocks))
+			}
+
+			combined, err := CombineDataBlocks(blocks)
+			if err != nil {
+				t.Fatalf("CombineDataBlocks: %v", err)
+			}
+			if !bytes.Equal(combined.Data[:len(payload)], payload) {
+				t.Fatalf("round-tripped payload mismatch:\n got  %x\n want %x", combined.Data[:len(payload)], payload)
+			}
+		})
+	}
+}
+
+// TestRate1ConfirmedBlockCRC9WireRoundTrip pins the confirmed-mode CRC-9
+// behavior for Rate 1 (crc9Masks[Rate1Data]) by serializing a block with
+// Bytes and parsing it back with ParseDataBlock, the only path that reads
+// a CRC-9 back off the wire instead of just computing one.
+func TestRate1ConfirmedBlockCRC9WireRoundTrip(t *testing.T) {
+	payload := make([]byte, userDataLength(Rate1Data, true))
+	for i := range payload {
+		payload[i] = byte(i * 3)
+	}
+
+	block := &DataBlock{Serial: 5, Data: payload}
+	wire := block.Bytes(Rate1Data, true)
+
+	parsed, err := ParseDataBlock(wire, Rate1Data, true)
+	if err != nil {
+		t.Fatalf("ParseDataBlock: %v", err)
+	}
+	if parsed.Serial != block.Serial {
+		t.Fatalf("Serial mismatch: got %d want %d", parsed.Serial, block.Serial)
+	}
+	if parsed.CRC != block.CRC {
+		t.Fatalf("CRC-9 mismatch: got %#04x want %#04x", parsed.CRC, block.CRC)
+	}
+	if !bytes.Equal(parsed.Data, payload) {
+		t.Fatalf("payload mismatch:\n got  %x\n want %x", parsed.Data, payload)
+	}
+}
@@ -0,0 +1,140 @@
+package dmr
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FragmentReassembler reassembles a DataFragment from DataBlocks that may
+// arrive out of order, interleaved with other traffic, or not at all. Unlike
+// CombineDataBlocks, which requires every block up front and in order, the
+// reassembler accepts blocks as they are received, keeps them in a map keyed
+// by serial, and reports which serials are still missing so a receiver
+// can drive selective ARQ retransmission requests per the confirmed-delivery
+// protocol. The CRC-32 carried in the final block is only checked once on
+// Finalize, not on every Push.
+type FragmentReassembler struct {
+	// Total is the number of blocks the fragment consists of, as
+	// announced out-of-band (e.g. in the confirmed data header's
+	// "blocks to follow" field). It must be set, either at construction
+	// or via SetTotal, before Push will report a fragment as done.
+	Total uint8
+
+	// Timeout is the maximum time allowed to elapse between the first
+	// Push and a successful Finalize before the fragment is considered
+	// stuck. A zero Timeout disables the check.
+	Timeout time.Duration
+
+	blocks  map[uint8]*DataBlock
+	started time.Time
+}
+
+// NewFragmentReassembler returns a FragmentReassembler for a fragment of
+// total blocks. If timeout is non-zero, Push and Finalize will start
+// failing once that long has elapsed since the first block was received.
+func NewFragmentReassembler(total uint8, timeout time.Duration) *FragmentReassembler {
+	r := &FragmentReassembler{Total: total, Timeout: timeout}
+	r.Reset()
+	return r
+}
+
+// SetTotal updates the expected number of blocks, for callers that learn it
+// after construction (or after the fragment reassembler has been Reset for
+// reuse). Any already-buffered block whose Serial is now out of range for
+// total is discarded, since Push only bounds-checks a block's Serial
+// against Total once Total is known.
+func (r *FragmentReassembler) SetTotal(total uint8) {
+	r.Total = total
+	for serial := range r.blocks {
+		if serial >= total {
+			delete(r.blocks, serial)
+		}
+	}
+}
+
+// Reset discards any blocks received so far and clears the timeout clock,
+// allowing the reassembler to be reused for the next fragment.
+func (r *FragmentReassembler) Reset() {
+	r.blocks = make(map[uint8]*DataBlock)
+	r.started = time.Time{}
+}
+
+func (r *FragmentReassembler) expired() bool {
+	return r.Timeout > 0 && !r.started.IsZero() && time.Since(r.started) > r.Timeout
+}
+
+// Push adds a received block to the reassembler. It returns true once every
+// block up to Total has been seen and Finalize can be called. Pushing a
+// block with a serial already received is a no-op; the duplicate is
+// discarded and the original is kept.
+func (r *FragmentReassembler) Push(block *DataBlock) (done bool, err error) {
+	if block == nil {
+		return false, errors.New("dmr: cannot push a nil data block")
+	}
+	if r.expired() {
+		return false, errors.New("dmr: fragment reassembly timed out")
+	}
+	if r.Total > 0 && block.Serial >= r.Total {
+		return false, fmt.Errorf("dmr: block serial %d is out of range for %d total blocks", block.Serial, r.Total)
+	}
+
+	if r.started.IsZero() {
+		r.started = time.Now()
+	}
+
+	if _, ok := r.blocks[block.Serial]; !ok {
+		r.blocks[block.Serial] = block
+	}
+
+	if r.Total == 0 {
+		// Total hasn't been set yet (e.g. SetTotal is called after
+		// inspecting the first block), so there's no way to know
+		// whether every block has arrived. Report not-done rather
+		// than let MissingBlocks' nil-for-Total-0 result read as
+		// "nothing missing".
+		return false, nil
+	}
+	return len(r.MissingBlocks()) == 0, nil
+}
+
+// MissingBlocks returns the serials of blocks that have not been received
+// yet, in ascending order. It is empty (not nil) once every block up to
+// Total has arrived, and nil if Total has not been set.
+func (r *FragmentReassembler) MissingBlocks() []uint8 {
+	if r.Total == 0 {
+		return nil
+	}
+	missing := []uint8{}
+	for serial := uint8(0); serial < r.Total; serial++ {
+		if _, ok := r.blocks[serial]; !ok {
+			missing = append(missing, serial)
+		}
+	}
+	return missing
+}
+
+// Finalize combines the received blocks into a DataFragment and verifies
+// its CRC-32, exactly as CombineDataBlocks does. It fails if Total has not
+// been set, if any block is still missing, or if the reassembly has timed
+// out.
+func (r *FragmentReassembler) Finalize() (*DataFragment, error) {
+	if r.Total == 0 {
+		return nil, errors.New("dmr: fragment reassembler has no total block count set")
+	}
+	if r.expired() {
+		return nil, errors.New("dmr: fragment reassembly timed out")
+	}
+	if missing := r.MissingBlocks(); len(missing) > 0 {
+		return nil, errors.New("dmr: fragment is missing blocks")
+	}
+
+	blocks := make([]*DataBlock, r.Total)
+	for serial, block := range r.blocks {
+		if serial >= r.Total {
+			return nil, fmt.Errorf("dmr: buffered block serial %d is out of range for %d total blocks", serial, r.Total)
+		}
+		blocks[serial] = block
+	}
+	return CombineDataBlocks(blocks)
+}
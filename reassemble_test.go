@@ -0,0 +1,127 @@
+package dmr
+
+import (
+	"testing"
+	"time"
+)
+
+func makeReassembleBlocks(t *testing.T, dataType uint8, confirmed bool, payload []byte) []*DataBlock {
+	t.Helper()
+	df := &DataFragment{Data: payload}
+	blocks, err := df.DataBlocks(dataType, confirmed)
+	if err != nil {
+		t.Fatalf("DataBlocks: %v", err)
+	}
+	return blocks
+}
+
+func TestFragmentReassemblerHappyPath(t *testing.T) {
+	payload := make([]byte, 50)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	blocks := makeReassembleBlocks(t, Rate1Data, true, payload)
+
+	r := NewFragmentReassembler(uint8(len(blocks)), 0)
+	for i, block := range blocks {
+		done, err := r.Push(block)
+		if err != nil {
+			t.Fatalf("Push(%d): %v", i, err)
+		}
+		if want := i == len(blocks)-1; done != want {
+			t.Fatalf("Push(%d) done = %v, want %v", i, done, want)
+		}
+	}
+
+	fragment, err := r.Finalize()
+	if err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+	if string(fragment.Data[:len(payload)]) != string(payload) {
+		t.Fatalf("reassembled payload mismatch:\n got  %x\n want %x", fragment.Data[:len(payload)], payload)
+	}
+}
+
+func TestFragmentReassemblerDuplicateBlockIsNoOp(t *testing.T) {
+	blocks := makeReassembleBlocks(t, Rate1Data, true, make([]byte, 50))
+	r := NewFragmentReassembler(uint8(len(blocks)), 0)
+
+	if _, err := r.Push(blocks[0]); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	// Push a distinct block value with the same serial; the original must
+	// be kept.
+	dup := &DataBlock{Serial: blocks[0].Serial, Length: blocks[0].Length, Data: make([]byte, len(blocks[0].Data))}
+	if _, err := r.Push(dup); err != nil {
+		t.Fatalf("Push duplicate: %v", err)
+	}
+	if r.blocks[blocks[0].Serial] != blocks[0] {
+		t.Fatalf("duplicate push replaced the original block")
+	}
+}
+
+func TestFragmentReassemblerRejectsOutOfRangeSerial(t *testing.T) {
+	r := NewFragmentReassembler(3, 0)
+	if _, err := r.Push(&DataBlock{Serial: 200, Length: 24, Data: make([]byte, 24)}); err == nil {
+		t.Fatalf("expected an error pushing a serial beyond Total")
+	}
+}
+
+func TestFragmentReassemblerSetTotalPrunesOutOfRangeBlocks(t *testing.T) {
+	r := &FragmentReassembler{}
+	r.Reset()
+
+	// Total is unset, so Push cannot bounds-check yet.
+	for _, serial := range []uint8{0, 1, 2, 200} {
+		if _, err := r.Push(&DataBlock{Serial: serial, Length: 24, Data: make([]byte, 24)}); err != nil {
+			t.Fatalf("Push(%d): %v", serial, err)
+		}
+	}
+
+	r.SetTotal(3)
+
+	if _, ok := r.blocks[200]; ok {
+		t.Fatalf("SetTotal did not prune the out-of-range serial 200")
+	}
+	if len(r.MissingBlocks()) != 0 {
+		t.Fatalf("MissingBlocks = %v, want none", r.MissingBlocks())
+	}
+	// Finalize must run CombineDataBlocks over the pruned set instead of
+	// panicking on the discarded out-of-range serial.
+	if _, err := r.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+}
+
+func TestFragmentReassemblerPushWithoutTotalNeverDone(t *testing.T) {
+	r := &FragmentReassembler{}
+	r.Reset()
+
+	done, err := r.Push(&DataBlock{Serial: 0, Length: 24, Data: make([]byte, 24)})
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if done {
+		t.Fatalf("Push reported done with Total unset")
+	}
+	if _, err := r.Finalize(); err == nil {
+		t.Fatalf("expected Finalize to fail with Total unset")
+	}
+}
+
+func TestFragmentReassemblerTimeout(t *testing.T) {
+	r := NewFragmentReassembler(2, time.Millisecond)
+	blocks := makeReassembleBlocks(t, Rate1Data, true, make([]byte, 20))
+
+	if _, err := r.Push(blocks[0]); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := r.Push(blocks[1]); err == nil {
+		t.Fatalf("expected Push to report the reassembly as timed out")
+	}
+	if _, err := r.Finalize(); err == nil {
+		t.Fatalf("expected Finalize to report the reassembly as timed out")
+	}
+}